@@ -0,0 +1,125 @@
+package merkle
+
+import (
+	"bytes"
+	"errors"
+)
+
+// ErrInvalidRange is returned by ConsistencyProof when m or n don't
+// describe a valid (old size, new size) pair for the tree.
+var ErrInvalidRange = errors.New("merkle: invalid consistency proof range")
+
+// ConsistencyProof returns the RFC 6962 consistency proof between the
+// first m leaves of t and its first n leaves (m <= n <= the tree's full
+// size), letting a verifier holding both roots confirm the size-n tree
+// is an append-only extension of the size-m tree. It only supports trees
+// built with ModeRFC6962.
+func (t *MerkleTree) ConsistencyProof(m, n int) ([][]byte, error) {
+	if t.mode != ModeRFC6962 {
+		return nil, ErrUnsupportedMode
+	}
+	if m < 0 || m > n || n > len(t.leaves) {
+		return nil, ErrInvalidRange
+	}
+	if m == 0 || m == n {
+		return nil, nil
+	}
+	hashes := leafHashes(t.leaves[:n])
+	return subProof(t.hasher, m, hashes, true), nil
+}
+
+func leafHashes(leaves []*node) [][]byte {
+	hashes := make([][]byte, len(leaves))
+	for i, n := range leaves {
+		hashes[i] = n.hash
+	}
+	return hashes
+}
+
+// mth is RFC 6962's Merkle Tree Hash of hashes, computed the same way
+// buildRFC6962 builds the tree itself: split at the largest power of two
+// below len(hashes) and recurse.
+func mth(hasher *Hasher, hashes [][]byte) []byte {
+	if len(hashes) == 1 {
+		return hashes[0]
+	}
+	k := largestPowerOfTwoBelow(len(hashes))
+	return hasher.nodeHash(mth(hasher, hashes[:k]), mth(hasher, hashes[k:]))
+}
+
+// subProof implements RFC 6962's SUBPROOF(m, D[n], b): b is true while
+// the subtree being recursed into is known to be the one the m-sized
+// proof is being issued against (so its hash doesn't need to be
+// included, the verifier already has it as the old root).
+func subProof(hasher *Hasher, m int, hashes [][]byte, b bool) [][]byte {
+	n := len(hashes)
+	if m == n {
+		if b {
+			return nil
+		}
+		return [][]byte{mth(hasher, hashes)}
+	}
+	k := largestPowerOfTwoBelow(n)
+	if m <= k {
+		proof := subProof(hasher, m, hashes[:k], b)
+		return append(proof, mth(hasher, hashes[k:]))
+	}
+	proof := subProof(hasher, m-k, hashes[k:], false)
+	return append(proof, mth(hasher, hashes[:k]))
+}
+
+// VerifyConsistency reports whether proof shows that the size-n SHA-256
+// tree with root newRoot is an append-only extension of the size-m tree
+// with root oldRoot.
+func VerifyConsistency(oldRoot, newRoot []byte, m, n int, proof [][]byte) bool {
+	return VerifyConsistencyWithHasher(DefaultHasher, oldRoot, newRoot, m, n, proof)
+}
+
+// VerifyConsistencyWithHasher is VerifyConsistency for trees built with
+// hasher.
+func VerifyConsistencyWithHasher(hasher *Hasher, oldRoot, newRoot []byte, m, n int, proof [][]byte) bool {
+	if m < 0 || n < m {
+		return false
+	}
+	if m == n {
+		return len(proof) == 0 && bytes.Equal(oldRoot, newRoot)
+	}
+	if m == 0 {
+		return len(proof) == 0
+	}
+	if len(proof) == 0 {
+		return false
+	}
+
+	node, lastNode := m-1, n-1
+	for node%2 == 1 {
+		node /= 2
+		lastNode /= 2
+	}
+
+	var first, second []byte
+	var rest [][]byte
+	if node == 0 {
+		first, second = oldRoot, oldRoot
+		rest = proof
+	} else {
+		first, second = proof[0], proof[0]
+		rest = proof[1:]
+	}
+
+	for _, h := range rest {
+		if node%2 == 1 || node == lastNode {
+			first = hasher.nodeHash(h, first)
+			second = hasher.nodeHash(h, second)
+			for node%2 == 0 && node != 0 {
+				node /= 2
+				lastNode /= 2
+			}
+		} else {
+			second = hasher.nodeHash(second, h)
+		}
+		node /= 2
+		lastNode /= 2
+	}
+	return bytes.Equal(first, oldRoot) && bytes.Equal(second, newRoot)
+}
@@ -0,0 +1,69 @@
+package merkle
+
+import "testing"
+
+func TestConsistencyProof_VerifiesAcrossAllSizePairs(t *testing.T) {
+	for n := 1; n <= 8; n++ {
+		newTree := NewTree(rfc6962Leaves[:n])
+		newRoot := newTree.Root()
+		for m := 1; m <= n; m++ {
+			oldTree := NewTree(rfc6962Leaves[:m])
+			oldRoot := oldTree.Root()
+
+			proof, err := newTree.ConsistencyProof(m, n)
+			if err != nil {
+				t.Fatalf("ConsistencyProof(%d, %d) error: %v", m, n, err)
+			}
+			if !VerifyConsistency(oldRoot, newRoot, m, n, proof) {
+				t.Errorf("VerifyConsistency(%d, %d) failed", m, n)
+			}
+		}
+	}
+}
+
+func TestVerifyConsistency_RejectsWrongOldRoot(t *testing.T) {
+	newTree := NewTree(rfc6962Leaves[:8])
+	proof, err := newTree.ConsistencyProof(5, 8)
+	if err != nil {
+		t.Fatalf("ConsistencyProof error: %v", err)
+	}
+	wrongRoot := NewTree(rfc6962Leaves[:4]).Root()
+	if VerifyConsistency(wrongRoot, newTree.Root(), 5, 8, proof) {
+		t.Error("VerifyConsistency accepted a mismatched old root")
+	}
+}
+
+func TestVerifyConsistency_RejectsTamperedProof(t *testing.T) {
+	newTree := NewTree(rfc6962Leaves[:7])
+	oldRoot := NewTree(rfc6962Leaves[:3]).Root()
+	proof, err := newTree.ConsistencyProof(3, 7)
+	if err != nil {
+		t.Fatalf("ConsistencyProof error: %v", err)
+	}
+	if len(proof) == 0 {
+		t.Fatal("expected a non-empty proof")
+	}
+	tampered := make([][]byte, len(proof))
+	copy(tampered, proof)
+	tamperedHash := append([]byte(nil), tampered[0]...)
+	tamperedHash[0] ^= 0xFF
+	tampered[0] = tamperedHash
+
+	if VerifyConsistency(oldRoot, newTree.Root(), 3, 7, tampered) {
+		t.Error("VerifyConsistency accepted a tampered proof")
+	}
+}
+
+func TestConsistencyProof_UnsupportedModeDuplicateLast(t *testing.T) {
+	tree := NewTreeWithOptions(rfc6962Leaves, Options{Mode: ModeDuplicateLast})
+	if _, err := tree.ConsistencyProof(3, 7); err != ErrUnsupportedMode {
+		t.Errorf("ConsistencyProof on ModeDuplicateLast tree = %v, want ErrUnsupportedMode", err)
+	}
+}
+
+func TestVerifyConsistency_EqualSizes(t *testing.T) {
+	root := NewTree(rfc6962Leaves[:5]).Root()
+	if !VerifyConsistency(root, root, 5, 5, nil) {
+		t.Error("VerifyConsistency(m, m) should hold trivially when roots match")
+	}
+}
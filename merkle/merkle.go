@@ -0,0 +1,318 @@
+// Package merkle implements a Merkle tree with RFC 6962 domain separation,
+// the scheme used by Certificate Transparency logs. Leaf hashes are
+// computed over a 0x00-prefixed input and internal-node hashes over a
+// 0x01-prefixed concatenation of their children, so an attacker cannot
+// present an internal node's hash as if it were a leaf (the classic
+// second-preimage attack against naive Merkle trees).
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"hash"
+	"sync"
+)
+
+const (
+	leafHashPrefix = 0x00
+	nodeHashPrefix = 0x01
+)
+
+// ErrLeafNotFound is returned by AuditPath when the requested leaf is not
+// part of the tree.
+var ErrLeafNotFound = errors.New("merkle: leaf not found")
+
+// HashFunc constructs a new hash.Hash, e.g. sha256.New or sha512.New. It
+// lets callers pick the hash algorithm a tree uses instead of being
+// locked into SHA-256.
+type HashFunc func() hash.Hash
+
+// Hasher computes RFC 6962 leaf and node hashes for a given HashFunc,
+// pooling the underlying hash.Hash instances so that building or walking
+// a large tree doesn't allocate one per call.
+type Hasher struct {
+	new  HashFunc
+	size int
+	pool sync.Pool
+}
+
+// NewHasher returns a Hasher backed by newHash.
+func NewHasher(newHash HashFunc) *Hasher {
+	h := &Hasher{new: newHash, size: newHash().Size()}
+	h.pool.New = func() any { return newHash() }
+	return h
+}
+
+// Size returns the number of bytes newHash's hash.Hash produces.
+func (h *Hasher) Size() int {
+	return h.size
+}
+
+func (h *Hasher) get() hash.Hash {
+	return h.pool.Get().(hash.Hash)
+}
+
+func (h *Hasher) put(hh hash.Hash) {
+	hh.Reset()
+	h.pool.Put(hh)
+}
+
+// leafHash computes the RFC 6962 leaf hash: Hash(0x00 || data).
+func (h *Hasher) leafHash(data []byte) []byte {
+	hh := h.get()
+	defer h.put(hh)
+	hh.Write([]byte{leafHashPrefix})
+	hh.Write(data)
+	return hh.Sum(nil)
+}
+
+// nodeHash computes the RFC 6962 internal-node hash: Hash(0x01 || left || right).
+func (h *Hasher) nodeHash(left, right []byte) []byte {
+	hh := h.get()
+	defer h.put(hh)
+	hh.Write([]byte{nodeHashPrefix})
+	hh.Write(left)
+	hh.Write(right)
+	return hh.Sum(nil)
+}
+
+// emptyHash computes RFC 6962's hash of the empty tree, MTH({}) = Hash(),
+// i.e. the hash of the empty string with no domain-separation prefix.
+func (h *Hasher) emptyHash() []byte {
+	hh := h.get()
+	defer h.put(hh)
+	return hh.Sum(nil)
+}
+
+// hashKey hashes key with no domain-separation prefix, giving a
+// fixed-length index into a SparseMerkleTree's key space. The index only
+// selects a path through the tree and is never itself hashed into a leaf
+// or node.
+func (h *Hasher) hashKey(key []byte) []byte {
+	hh := h.get()
+	defer h.put(hh)
+	hh.Write(key)
+	return hh.Sum(nil)
+}
+
+// DefaultHasher is the SHA-256 Hasher used when a tree is built without
+// an explicit HashFunc.
+var DefaultHasher = NewHasher(sha256.New)
+
+// NewDoubleSHA256 returns a hash.Hash that hashes with SHA-256 twice
+// (Hash(x) = SHA256(SHA256(x))), the scheme Bitcoin uses for its Merkle
+// trees. It can be passed to NewHasher and used via Options.Hasher with
+// NewTreeWithOptions.
+func NewDoubleSHA256() hash.Hash {
+	return &doubleSHA256{inner: sha256.New()}
+}
+
+type doubleSHA256 struct {
+	inner hash.Hash
+}
+
+func (d *doubleSHA256) Write(p []byte) (int, error) { return d.inner.Write(p) }
+func (d *doubleSHA256) Reset()                      { d.inner.Reset() }
+func (d *doubleSHA256) Size() int                   { return d.inner.Size() }
+func (d *doubleSHA256) BlockSize() int              { return d.inner.BlockSize() }
+
+func (d *doubleSHA256) Sum(b []byte) []byte {
+	first := d.inner.Sum(nil)
+	second := sha256.Sum256(first)
+	return append(b, second[:]...)
+}
+
+// node is an internal or leaf node.
+type node struct {
+	hash                []byte
+	parent, left, right *node
+}
+
+// MerkleTree is an RFC 6962 Merkle tree built over an ordered list of
+// leaves.
+type MerkleTree struct {
+	hasher *Hasher
+	mode   TreeMode
+	root   *node
+	leaves []*node
+}
+
+// ProofStep is one step of an audit (inclusion) path: the hash of a
+// sibling node and whether that sibling sits to the left of the node on
+// the path from the leaf being proved.
+type ProofStep struct {
+	Hash []byte
+	Left bool
+}
+
+// TreeMode selects how a tree pads an odd-length level when building
+// parents.
+type TreeMode int
+
+const (
+	// ModeRFC6962 builds the tree by recursively splitting the leaves at
+	// the largest power of two strictly less than their count (RFC 6962's
+	// MTH). No leaf is ever duplicated, so the tree matches Certificate
+	// Transparency logs and stays second-preimage-safe for any leaf
+	// count. This is the default.
+	ModeRFC6962 TreeMode = iota
+	// ModeDuplicateLast pads an odd level by duplicating its last node,
+	// Bitcoin Merkle-tree style, producing a balanced binary tree.
+	ModeDuplicateLast
+)
+
+// Options configures NewTreeWithOptions. The zero value uses SHA-256 and
+// ModeRFC6962.
+type Options struct {
+	Hasher *Hasher
+	Mode   TreeMode
+}
+
+// NewTree builds a Merkle tree over leaves, in order, using SHA-256 and
+// ModeRFC6962.
+func NewTree(leaves [][]byte) *MerkleTree {
+	return NewTreeWithOptions(leaves, Options{})
+}
+
+// NewTreeWithOptions builds a Merkle tree over leaves using opts, letting
+// callers choose the hash algorithm and the odd-level padding strategy.
+func NewTreeWithOptions(leaves [][]byte, opts Options) *MerkleTree {
+	hasher := opts.Hasher
+	if hasher == nil {
+		hasher = DefaultHasher
+	}
+	leafNodes := buildLeaves(leaves, hasher)
+
+	var root *node
+	switch {
+	case len(leafNodes) == 0:
+		root = &node{hash: hasher.emptyHash()}
+	case opts.Mode == ModeDuplicateLast:
+		root = buildDuplicateLast(leafNodes, hasher)
+	default:
+		root = buildRFC6962(leafNodes, hasher)
+	}
+	return &MerkleTree{hasher: hasher, mode: opts.Mode, root: root, leaves: leafNodes}
+}
+
+func buildLeaves(leaves [][]byte, hasher *Hasher) []*node {
+	nodes := make([]*node, len(leaves))
+	for i, leaf := range leaves {
+		nodes[i] = &node{hash: hasher.leafHash(leaf)}
+	}
+	return nodes
+}
+
+// buildDuplicateLast reduces a level of nodes to its parent, pairing
+// neighbours and, for an odd node out, duplicating the last node.
+func buildDuplicateLast(level []*node, hasher *Hasher) *node {
+	if len(level) == 1 {
+		return level[0]
+	}
+	var parents []*node
+	for i := 0; i < len(level); i += 2 {
+		left := level[i]
+		right := left
+		if i+1 < len(level) {
+			right = level[i+1]
+		}
+		parent := &node{hash: hasher.nodeHash(left.hash, right.hash), left: left, right: right}
+		left.parent = parent
+		right.parent = parent
+		parents = append(parents, parent)
+	}
+	return buildDuplicateLast(parents, hasher)
+}
+
+// buildRFC6962 builds the RFC 6962 MTH tree over leaves: split at the
+// largest power of two strictly less than len(leaves), recurse into both
+// halves, and hash the two subtree roots together. Unlike
+// buildDuplicateLast, no leaf is ever paired with itself.
+func buildRFC6962(leaves []*node, hasher *Hasher) *node {
+	if len(leaves) == 1 {
+		return leaves[0]
+	}
+	k := largestPowerOfTwoBelow(len(leaves))
+	left := buildRFC6962(leaves[:k], hasher)
+	right := buildRFC6962(leaves[k:], hasher)
+	parent := &node{hash: hasher.nodeHash(left.hash, right.hash), left: left, right: right}
+	left.parent = parent
+	right.parent = parent
+	return parent
+}
+
+// largestPowerOfTwoBelow returns the largest power of two strictly less
+// than n, for n > 1.
+func largestPowerOfTwoBelow(n int) int {
+	k := 1
+	for k*2 < n {
+		k *= 2
+	}
+	return k
+}
+
+// Root returns the tree's root hash.
+func (t *MerkleTree) Root() []byte {
+	return t.root.hash
+}
+
+// Mode reports the TreeMode the tree was built with.
+func (t *MerkleTree) Mode() TreeMode {
+	return t.mode
+}
+
+// AuditPath returns the inclusion proof for leaf: an ordered list of
+// sibling hashes from the leaf up to the root. It returns ErrLeafNotFound
+// if leaf is not one of the tree's leaves.
+func (t *MerkleTree) AuditPath(leaf []byte) ([]ProofStep, error) {
+	target := t.hasher.leafHash(leaf)
+	for _, n := range t.leaves {
+		if bytes.Equal(n.hash, target) {
+			return auditPath(n), nil
+		}
+	}
+	return nil, ErrLeafNotFound
+}
+
+func auditPath(n *node) []ProofStep {
+	var path []ProofStep
+	for n.parent != nil {
+		parent := n.parent
+		left := parent.left == n
+		sibling := parent.right
+		if !left {
+			sibling = parent.left
+		}
+		path = append(path, ProofStep{Hash: sibling.hash, Left: !left})
+		n = parent
+	}
+	return path
+}
+
+// VerifyProof reports whether proof is a valid RFC 6962 inclusion proof
+// showing that leaf is part of a SHA-256 tree with the given root.
+func VerifyProof(root []byte, leaf []byte, proof []ProofStep) bool {
+	return VerifyProofWithHasher(DefaultHasher, root, leaf, proof)
+}
+
+// VerifyProofWithHasher is VerifyProof for a tree built with hasher.
+func VerifyProofWithHasher(hasher *Hasher, root []byte, leaf []byte, proof []ProofStep) bool {
+	return bytes.Equal(replayPath(hasher, hasher.leafHash(leaf), proof), root)
+}
+
+// replayPath recomputes a root hash by combining leaf with each sibling
+// in proof, leaf-to-root. It is shared by MerkleTree and
+// SparseMerkleTree proof verification, which differ only in how they
+// derive leaf and validate proof against an expected path.
+func replayPath(hasher *Hasher, leaf []byte, proof []ProofStep) []byte {
+	current := leaf
+	for _, step := range proof {
+		if step.Left {
+			current = hasher.nodeHash(step.Hash, current)
+		} else {
+			current = hasher.nodeHash(current, step.Hash)
+		}
+	}
+	return current
+}
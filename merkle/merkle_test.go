@@ -0,0 +1,150 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// rfc6962Leaves are the eight test leaves used by Google's Trillian/CT
+// reference implementation (certificate-transparency-go's
+// merkletree_test.go), reused here so this package's root hashes are
+// known to interoperate with CT-style verifiers.
+var rfc6962Leaves = [][]byte{
+	{},
+	{0x00},
+	{0x10},
+	{0x20, 0x21},
+	{0x30, 0x31},
+	{0x40, 0x41, 0x42, 0x43},
+	{0x50, 0x51, 0x52, 0x53, 0x54, 0x55, 0x56, 0x57},
+	{0x60, 0x61, 0x62, 0x63, 0x64, 0x65, 0x66, 0x67, 0x68, 0x69, 0x6a, 0x6b, 0x6c, 0x6d, 0x6e, 0x6f},
+}
+
+// rfc6962Roots holds the known root hash for the first n leaves, for the
+// tree sizes that are a power of two (where pairwise duplication of the
+// last node and RFC 6962's recursive split agree).
+var rfc6962Roots = map[int]string{
+	1: "6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d",
+	2: "fac54203e7cc696cf0dfcb42c92a1d9dbaf70ad9e621f4bd8d98662f00e3c125",
+	4: "d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+	8: "5dc9da79a70659a9ad559cb701ded9a2ab9d823aad2f4960cfe370eff4604328",
+}
+
+func TestRoot_RFC6962Vectors(t *testing.T) {
+	for n, want := range rfc6962Roots {
+		tree := NewTree(rfc6962Leaves[:n])
+		got := hex.EncodeToString(tree.Root())
+		if got != want {
+			t.Errorf("Root() for %d leaves = %s, want %s", n, got, want)
+		}
+	}
+}
+
+func TestLeafHash_RFC6962(t *testing.T) {
+	// SHA-256(0x00) is the well-known RFC 6962 hash of the empty leaf.
+	want := "6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d"
+	if got := hex.EncodeToString(DefaultHasher.leafHash(nil)); got != want {
+		t.Errorf("leafHash(nil) = %s, want %s", got, want)
+	}
+}
+
+func TestNewTreeWithOptions_DoubleSHA256(t *testing.T) {
+	hasher := NewHasher(NewDoubleSHA256)
+	tree := NewTreeWithOptions(rfc6962Leaves, Options{Hasher: hasher})
+	root := tree.Root()
+
+	for _, leaf := range rfc6962Leaves {
+		proof, err := tree.AuditPath(leaf)
+		if err != nil {
+			t.Fatalf("AuditPath(%x) returned error: %v", leaf, err)
+		}
+		if !VerifyProofWithHasher(hasher, root, leaf, proof) {
+			t.Errorf("VerifyProofWithHasher failed for leaf %x", leaf)
+		}
+	}
+}
+
+func TestAuditPath_VerifyProof(t *testing.T) {
+	tree := NewTree(rfc6962Leaves)
+	root := tree.Root()
+
+	for _, leaf := range rfc6962Leaves {
+		proof, err := tree.AuditPath(leaf)
+		if err != nil {
+			t.Fatalf("AuditPath(%x) returned error: %v", leaf, err)
+		}
+		if !VerifyProof(root, leaf, proof) {
+			t.Errorf("VerifyProof failed for leaf %x", leaf)
+		}
+	}
+}
+
+func TestRoot_OddLeafCountRFC6962Vectors(t *testing.T) {
+	// n=3,5,6,7 are not powers of two, so they only agree with Trillian's
+	// published vectors under the default ModeRFC6962 split.
+	want := map[int]string{
+		3: "aeb6bcfe274b70a14fb067a5e5578264db0fa9b51af5e0ba159158f329e06e77",
+		5: "4e3bbb1f7b478dcfe71fb631631519a3bca12c9aefca1612bfce4c13a86264d4",
+		6: "76e67dadbcdf1e10e1b74ddc608abd2f98dfb16fbce75277b5232a127f2087ef",
+		7: "ddb89be403809e325750d3d263cd78929c2942b7942a34b77e122c9594a74c8c",
+	}
+	for n, w := range want {
+		tree := NewTree(rfc6962Leaves[:n])
+		if got := hex.EncodeToString(tree.Root()); got != w {
+			t.Errorf("Root() for %d leaves = %s, want %s", n, got, w)
+		}
+	}
+}
+
+func TestNewTreeWithOptions_BothModesProduceValidProofs(t *testing.T) {
+	for _, mode := range []TreeMode{ModeRFC6962, ModeDuplicateLast} {
+		tree := NewTreeWithOptions(rfc6962Leaves[:7], Options{Mode: mode})
+		root := tree.Root()
+		for _, leaf := range rfc6962Leaves[:7] {
+			proof, err := tree.AuditPath(leaf)
+			if err != nil {
+				t.Fatalf("mode %v: AuditPath(%x) returned error: %v", mode, leaf, err)
+			}
+			if !VerifyProof(root, leaf, proof) {
+				t.Errorf("mode %v: VerifyProof failed for leaf %x", mode, leaf)
+			}
+		}
+	}
+}
+
+func TestNewTreeWithOptions_ModesDisagreeOnOddCounts(t *testing.T) {
+	rfc6962 := NewTreeWithOptions(rfc6962Leaves[:7], Options{Mode: ModeRFC6962})
+	duplicateLast := NewTreeWithOptions(rfc6962Leaves[:7], Options{Mode: ModeDuplicateLast})
+	if bytes.Equal(rfc6962.Root(), duplicateLast.Root()) {
+		t.Error("expected ModeRFC6962 and ModeDuplicateLast to disagree for an odd leaf count")
+	}
+}
+
+func TestNewTree_EmptyTree(t *testing.T) {
+	tree := NewTree(nil)
+	want := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	if got := hex.EncodeToString(tree.Root()); got != want {
+		t.Errorf("Root() for empty tree = %s, want %s", got, want)
+	}
+}
+
+func TestAuditPath_NotFound(t *testing.T) {
+	tree := NewTree(rfc6962Leaves)
+	if _, err := tree.AuditPath([]byte("not a leaf")); err != ErrLeafNotFound {
+		t.Errorf("AuditPath for missing leaf = %v, want ErrLeafNotFound", err)
+	}
+}
+
+func TestVerifyProof_RejectsTampering(t *testing.T) {
+	tree := NewTree(rfc6962Leaves)
+	root := tree.Root()
+
+	proof, err := tree.AuditPath(rfc6962Leaves[0])
+	if err != nil {
+		t.Fatalf("AuditPath returned error: %v", err)
+	}
+	if VerifyProof(root, []byte("wrong leaf"), proof) {
+		t.Error("VerifyProof accepted a proof for the wrong leaf")
+	}
+}
@@ -0,0 +1,66 @@
+package merkle
+
+import (
+	"encoding/gob"
+	"io"
+)
+
+// gobNode mirrors node for serialization: a plain tree of hashes with no
+// parent pointers, which SaveTree/LoadTree reconstruct on load.
+type gobNode struct {
+	Hash        []byte
+	Left, Right *gobNode
+}
+
+type treeSnapshot struct {
+	Mode TreeMode
+	Root *gobNode
+}
+
+// SaveTree writes t to w so it can later be restored with LoadTree
+// without recomputing any hash. It does not record which Hasher built t;
+// LoadTree (or LoadTreeWithHasher) must be given one that matches.
+func SaveTree(w io.Writer, t *MerkleTree) error {
+	snap := treeSnapshot{Mode: t.mode, Root: toGobNode(t.root)}
+	return gob.NewEncoder(w).Encode(snap)
+}
+
+// LoadTree reads a tree written by SaveTree, assuming it was built with
+// DefaultHasher (SHA-256).
+func LoadTree(r io.Reader) (*MerkleTree, error) {
+	return LoadTreeWithHasher(r, DefaultHasher)
+}
+
+// LoadTreeWithHasher reads a tree written by SaveTree, for a tree that
+// was built with hasher.
+func LoadTreeWithHasher(r io.Reader, hasher *Hasher) (*MerkleTree, error) {
+	var snap treeSnapshot
+	if err := gob.NewDecoder(r).Decode(&snap); err != nil {
+		return nil, err
+	}
+	root, leaves := fromGobNode(snap.Root, nil)
+	return &MerkleTree{hasher: hasher, mode: snap.Mode, root: root, leaves: leaves}, nil
+}
+
+func toGobNode(n *node) *gobNode {
+	if n == nil {
+		return nil
+	}
+	return &gobNode{Hash: n.hash, Left: toGobNode(n.left), Right: toGobNode(n.right)}
+}
+
+// fromGobNode rebuilds a node tree from gn, wiring parent pointers and
+// collecting leaves left-to-right.
+func fromGobNode(gn *gobNode, parent *node) (root *node, leaves []*node) {
+	if gn == nil {
+		return nil, nil
+	}
+	n := &node{hash: gn.Hash, parent: parent}
+	if gn.Left == nil && gn.Right == nil {
+		return n, []*node{n}
+	}
+	left, leftLeaves := fromGobNode(gn.Left, n)
+	right, rightLeaves := fromGobNode(gn.Right, n)
+	n.left, n.right = left, right
+	return n, append(leftLeaves, rightLeaves...)
+}
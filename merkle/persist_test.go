@@ -0,0 +1,39 @@
+package merkle
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSaveLoadTree_RoundTrip(t *testing.T) {
+	for _, mode := range []TreeMode{ModeRFC6962, ModeDuplicateLast} {
+		original := NewTreeWithOptions(rfc6962Leaves[:7], Options{Mode: mode})
+
+		var buf bytes.Buffer
+		if err := SaveTree(&buf, original); err != nil {
+			t.Fatalf("mode %v: SaveTree error: %v", mode, err)
+		}
+
+		loaded, err := LoadTree(&buf)
+		if err != nil {
+			t.Fatalf("mode %v: LoadTree error: %v", mode, err)
+		}
+
+		if !bytes.Equal(loaded.Root(), original.Root()) {
+			t.Fatalf("mode %v: loaded root = %x, want %x", mode, loaded.Root(), original.Root())
+		}
+		if loaded.Mode() != mode {
+			t.Errorf("mode %v: loaded.Mode() = %v", mode, loaded.Mode())
+		}
+
+		for _, leaf := range rfc6962Leaves[:7] {
+			proof, err := loaded.AuditPath(leaf)
+			if err != nil {
+				t.Fatalf("mode %v: AuditPath on loaded tree error: %v", mode, err)
+			}
+			if !VerifyProof(loaded.Root(), leaf, proof) {
+				t.Errorf("mode %v: VerifyProof failed on loaded tree for leaf %x", mode, leaf)
+			}
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// ErrUnsupportedMode is returned when an operation that assumes a
+// particular TreeMode is used with a tree built in a different mode.
+var ErrUnsupportedMode = errors.New("merkle: operation not supported for this TreeMode")
+
+// Proof is a compact, wire-friendly inclusion proof for a ModeRFC6962
+// tree: a leaf index, the tree size it was issued against, and the raw
+// sibling hashes from leaf to root. Left/right orientation is not
+// stored; it is derived from LeafIndex and TreeSize with the same
+// recursive split used to build the tree, RFC 6962 style.
+type Proof struct {
+	LeafIndex uint64   `json:"leafIndex"`
+	TreeSize  uint64   `json:"treeSize"`
+	Hashes    [][]byte `json:"hashes"`
+}
+
+// Proof returns the compact inclusion proof for leaf. It only supports
+// trees built with ModeRFC6962, since ModeDuplicateLast's padding means
+// sibling orientation can't be derived from the leaf index alone; use
+// AuditPath for those.
+func (t *MerkleTree) Proof(leaf []byte) (Proof, error) {
+	if t.mode != ModeRFC6962 {
+		return Proof{}, ErrUnsupportedMode
+	}
+	target := t.hasher.leafHash(leaf)
+	for i, n := range t.leaves {
+		if bytes.Equal(n.hash, target) {
+			steps := auditPath(n)
+			hashes := make([][]byte, len(steps))
+			for j, step := range steps {
+				hashes[j] = step.Hash
+			}
+			return Proof{LeafIndex: uint64(i), TreeSize: uint64(len(t.leaves)), Hashes: hashes}, nil
+		}
+	}
+	return Proof{}, ErrLeafNotFound
+}
+
+// Verify reports whether p is a valid inclusion proof for leaf against
+// root, for a SHA-256 ModeRFC6962 tree.
+func (p Proof) Verify(root, leaf []byte) bool {
+	return p.VerifyWithHasher(DefaultHasher, root, leaf)
+}
+
+// VerifyWithHasher is Verify for a tree built with hasher.
+func (p Proof) VerifyWithHasher(hasher *Hasher, root, leaf []byte) bool {
+	directions := pathDirections(int(p.LeafIndex), int(p.TreeSize))
+	if len(directions) != len(p.Hashes) {
+		return false
+	}
+	steps := make([]ProofStep, len(p.Hashes))
+	for i, h := range p.Hashes {
+		steps[i] = ProofStep{Hash: h, Left: directions[i]}
+	}
+	return bytes.Equal(replayPath(hasher, hasher.leafHash(leaf), steps), root)
+}
+
+// pathDirections returns, leaf-to-root, whether each proof step's
+// sibling sits to the left of the node on the path from leaf index to
+// the root of a ModeRFC6962 tree of the given size. This mirrors
+// buildRFC6962's split exactly, so it reproduces the same orientation
+// the tree itself would report via AuditPath.
+func pathDirections(index, size int) []bool {
+	directions := rootToLeafDirections(index, size)
+	rev := make([]bool, len(directions))
+	for i, v := range directions {
+		rev[len(directions)-1-i] = v
+	}
+	return rev
+}
+
+func rootToLeafDirections(index, size int) []bool {
+	if size <= 1 {
+		return nil
+	}
+	k := largestPowerOfTwoBelow(size)
+	if index < k {
+		return append([]bool{false}, rootToLeafDirections(index, k)...) // sibling is the right subtree
+	}
+	return append([]bool{true}, rootToLeafDirections(index-k, size-k)...) // sibling is the left subtree
+}
+
+// MarshalBinary encodes p as: varint(LeafIndex), varint(TreeSize),
+// varint(hash length), varint(hash count), followed by the raw
+// concatenated sibling hashes. All hashes are assumed to share one
+// length, true of any proof produced by a single Hasher.
+func (p Proof) MarshalBinary() ([]byte, error) {
+	hashLen := 0
+	if len(p.Hashes) > 0 {
+		hashLen = len(p.Hashes[0])
+	}
+	buf := make([]byte, 0, 4*binary.MaxVarintLen64+len(p.Hashes)*hashLen)
+	var scratch [binary.MaxVarintLen64]byte
+
+	appendUvarint := func(v uint64) {
+		n := binary.PutUvarint(scratch[:], v)
+		buf = append(buf, scratch[:n]...)
+	}
+	appendUvarint(p.LeafIndex)
+	appendUvarint(p.TreeSize)
+	appendUvarint(uint64(hashLen))
+	appendUvarint(uint64(len(p.Hashes)))
+	for _, h := range p.Hashes {
+		if len(h) != hashLen {
+			return nil, fmt.Errorf("merkle: proof hashes have inconsistent lengths (%d and %d)", hashLen, len(h))
+		}
+		buf = append(buf, h...)
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary decodes a Proof encoded by MarshalBinary.
+func (p *Proof) UnmarshalBinary(data []byte) error {
+	leafIndex, n, err := readUvarint(data)
+	if err != nil {
+		return err
+	}
+	data = data[n:]
+
+	treeSize, n, err := readUvarint(data)
+	if err != nil {
+		return err
+	}
+	data = data[n:]
+
+	hashLen, n, err := readUvarint(data)
+	if err != nil {
+		return err
+	}
+	data = data[n:]
+
+	count, n, err := readUvarint(data)
+	if err != nil {
+		return err
+	}
+	data = data[n:]
+
+	// Validate count and hashLen against the actual remaining data before
+	// trusting either: an attacker-controlled count could otherwise blow
+	// up the make() below, and hashLen*count can overflow uint64 and wrap
+	// around to pass a naive length check.
+	if hashLen == 0 {
+		if count != 0 {
+			return errors.New("merkle: proof encodes zero-length hashes with a non-zero count")
+		}
+	} else if count > uint64(len(data))/hashLen {
+		return errors.New("merkle: proof hash count exceeds available data")
+	}
+	if uint64(len(data)) != hashLen*count {
+		return errors.New("merkle: proof data length does not match encoded hash count")
+	}
+	hashes := make([][]byte, count)
+	for i := range hashes {
+		hashes[i] = append([]byte(nil), data[:hashLen]...)
+		data = data[hashLen:]
+	}
+
+	p.LeafIndex = leafIndex
+	p.TreeSize = treeSize
+	p.Hashes = hashes
+	return nil
+}
+
+func readUvarint(data []byte) (uint64, int, error) {
+	v, n := binary.Uvarint(data)
+	if n <= 0 {
+		return 0, 0, errors.New("merkle: malformed varint in proof data")
+	}
+	return v, n, nil
+}
@@ -0,0 +1,127 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+)
+
+func TestProof_MatchesAuditPath(t *testing.T) {
+	for n := 1; n <= 8; n++ {
+		tree := NewTree(rfc6962Leaves[:n])
+		root := tree.Root()
+		for _, leaf := range rfc6962Leaves[:n] {
+			want, err := tree.AuditPath(leaf)
+			if err != nil {
+				t.Fatalf("n=%d: AuditPath error: %v", n, err)
+			}
+			proof, err := tree.Proof(leaf)
+			if err != nil {
+				t.Fatalf("n=%d: Proof error: %v", n, err)
+			}
+			if len(proof.Hashes) != len(want) {
+				t.Fatalf("n=%d: Proof has %d hashes, AuditPath has %d", n, len(proof.Hashes), len(want))
+			}
+			for i, step := range want {
+				if !bytes.Equal(proof.Hashes[i], step.Hash) {
+					t.Errorf("n=%d: Proof.Hashes[%d] = %x, want %x", n, i, proof.Hashes[i], step.Hash)
+				}
+			}
+			if !proof.Verify(root, leaf) {
+				t.Errorf("n=%d: Proof.Verify failed for leaf %x", n, leaf)
+			}
+		}
+	}
+}
+
+func TestProof_UnsupportedModeDuplicateLast(t *testing.T) {
+	tree := NewTreeWithOptions(rfc6962Leaves, Options{Mode: ModeDuplicateLast})
+	if _, err := tree.Proof(rfc6962Leaves[0]); err != ErrUnsupportedMode {
+		t.Errorf("Proof on ModeDuplicateLast tree = %v, want ErrUnsupportedMode", err)
+	}
+}
+
+func TestProof_MarshalUnmarshalBinary(t *testing.T) {
+	tree := NewTree(rfc6962Leaves)
+	root := tree.Root()
+	proof, err := tree.Proof(rfc6962Leaves[3])
+	if err != nil {
+		t.Fatalf("Proof error: %v", err)
+	}
+
+	data, err := proof.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary error: %v", err)
+	}
+
+	var decoded Proof
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary error: %v", err)
+	}
+	if decoded.LeafIndex != proof.LeafIndex || decoded.TreeSize != proof.TreeSize {
+		t.Fatalf("decoded = %+v, want %+v", decoded, proof)
+	}
+	if !decoded.Verify(root, rfc6962Leaves[3]) {
+		t.Error("decoded proof failed to verify")
+	}
+}
+
+func TestProof_UnmarshalBinary_RejectsOversizedCount(t *testing.T) {
+	var buf []byte
+	var scratch [binary.MaxVarintLen64]byte
+	appendUvarint := func(v uint64) {
+		n := binary.PutUvarint(scratch[:], v)
+		buf = append(buf, scratch[:n]...)
+	}
+	appendUvarint(0)       // LeafIndex
+	appendUvarint(0)       // TreeSize
+	appendUvarint(0)       // hashLen = 0
+	appendUvarint(1 << 40) // count, wildly larger than the data that follows
+
+	var p Proof
+	if err := p.UnmarshalBinary(buf); err == nil {
+		t.Fatal("UnmarshalBinary accepted a huge count with hashLen=0, want an error")
+	}
+}
+
+func TestProof_UnmarshalBinary_RejectsOverflowingLength(t *testing.T) {
+	var buf []byte
+	var scratch [binary.MaxVarintLen64]byte
+	appendUvarint := func(v uint64) {
+		n := binary.PutUvarint(scratch[:], v)
+		buf = append(buf, scratch[:n]...)
+	}
+	appendUvarint(0)       // LeafIndex
+	appendUvarint(0)       // TreeSize
+	appendUvarint(1 << 32) // hashLen
+	appendUvarint(1 << 33) // count; hashLen*count overflows uint64 and could wrap to a small value
+	buf = append(buf, []byte("short data")...)
+
+	var p Proof
+	if err := p.UnmarshalBinary(buf); err == nil {
+		t.Fatal("UnmarshalBinary accepted an overflowing hashLen*count, want an error")
+	}
+}
+
+func TestProof_JSONRoundTrip(t *testing.T) {
+	tree := NewTree(rfc6962Leaves)
+	root := tree.Root()
+	proof, err := tree.Proof(rfc6962Leaves[5])
+	if err != nil {
+		t.Fatalf("Proof error: %v", err)
+	}
+
+	data, err := json.Marshal(proof)
+	if err != nil {
+		t.Fatalf("json.Marshal error: %v", err)
+	}
+
+	var decoded Proof
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal error: %v", err)
+	}
+	if !decoded.Verify(root, rfc6962Leaves[5]) {
+		t.Error("decoded proof failed to verify")
+	}
+}
@@ -0,0 +1,191 @@
+package merkle
+
+import (
+	"bytes"
+	"sort"
+)
+
+// SparseMerkleTree authenticates a map of keys to values, as in CONIKS,
+// rather than an ordered list. A key is hashed to a fixed-length index
+// that determines its root-to-leaf path in a binary tree of depth
+// Hasher.Size()*8; an empty subtree's hash collapses to a precomputed
+// "default hash" for its height, so storage and proof-generation cost is
+// O(n) in the number of keys actually set rather than O(2^depth).
+type SparseMerkleTree struct {
+	hasher *Hasher
+	depth  int
+	// defaultHash[h] is the hash of an empty subtree of height h, with
+	// h=0 at the leaf level.
+	defaultHash [][]byte
+	// entries holds the set keys, sorted ascending by index so that a
+	// level's bit splits entries into a contiguous "left" and "right"
+	// run without needing an explicit node tree.
+	entries []smtEntry
+}
+
+type smtEntry struct {
+	index    []byte
+	value    []byte
+	leafHash []byte
+}
+
+// NewSparseMerkleTree returns an empty SparseMerkleTree using SHA-256.
+func NewSparseMerkleTree() *SparseMerkleTree {
+	return NewSparseMerkleTreeWithHasher(DefaultHasher)
+}
+
+// NewSparseMerkleTreeWithHasher returns an empty SparseMerkleTree using
+// hasher, whose path depth is hasher.Size()*8 bits.
+func NewSparseMerkleTreeWithHasher(hasher *Hasher) *SparseMerkleTree {
+	depth := hasher.Size() * 8
+	defaultHash := make([][]byte, depth+1)
+	defaultHash[0] = make([]byte, hasher.Size())
+	for h := 1; h <= depth; h++ {
+		defaultHash[h] = hasher.nodeHash(defaultHash[h-1], defaultHash[h-1])
+	}
+	return &SparseMerkleTree{hasher: hasher, depth: depth, defaultHash: defaultHash}
+}
+
+// Set authenticates value under key, replacing any value previously set
+// for key.
+func (t *SparseMerkleTree) Set(key, value []byte) {
+	idx := t.hasher.hashKey(key)
+	entry := smtEntry{index: idx, value: value, leafHash: t.hasher.leafHash(value)}
+
+	i := t.search(idx)
+	if i < len(t.entries) && bytes.Equal(t.entries[i].index, idx) {
+		t.entries[i] = entry
+		return
+	}
+	t.entries = append(t.entries, smtEntry{})
+	copy(t.entries[i+1:], t.entries[i:])
+	t.entries[i] = entry
+}
+
+// Get returns the value set for key, its authentication proof, and
+// whether key has a value set. The proof is valid for VerifyInclusion
+// when found is true and for VerifyNonInclusion otherwise.
+func (t *SparseMerkleTree) Get(key []byte) (value []byte, proof []ProofStep, found bool) {
+	idx := t.hasher.hashKey(key)
+	i := t.search(idx)
+	found = i < len(t.entries) && bytes.Equal(t.entries[i].index, idx)
+	if found {
+		value = t.entries[i].value
+	}
+	return value, t.auditPath(idx), found
+}
+
+func (t *SparseMerkleTree) search(idx []byte) int {
+	return sort.Search(len(t.entries), func(i int) bool {
+		return bytes.Compare(t.entries[i].index, idx) >= 0
+	})
+}
+
+// Root returns the tree's root hash.
+func (t *SparseMerkleTree) Root() []byte {
+	return t.subtreeHash(t.entries, 0)
+}
+
+// subtreeHash returns the hash of the subtree at depth bit containing
+// entries, all of which share the same index prefix up to bit.
+func (t *SparseMerkleTree) subtreeHash(entries []smtEntry, bit int) []byte {
+	if len(entries) == 0 {
+		return t.defaultHash[t.depth-bit]
+	}
+	if bit == t.depth {
+		return entries[0].leafHash
+	}
+	split := splitAtBit(entries, bit)
+	left := t.subtreeHash(entries[:split], bit+1)
+	right := t.subtreeHash(entries[split:], bit+1)
+	return t.hasher.nodeHash(left, right)
+}
+
+// auditPath returns the authentication path for idx, leaf-to-root, by
+// walking the same bit splits used to compute Root.
+func (t *SparseMerkleTree) auditPath(idx []byte) []ProofStep {
+	steps := t.pathSteps(t.entries, 0, idx)
+	for i, j := 0, len(steps)-1; i < j; i, j = i+1, j-1 {
+		steps[i], steps[j] = steps[j], steps[i]
+	}
+	return steps
+}
+
+// pathSteps returns the authentication path for idx, root-to-leaf.
+func (t *SparseMerkleTree) pathSteps(entries []smtEntry, bit int, idx []byte) []ProofStep {
+	if bit == t.depth {
+		return nil
+	}
+	split := splitAtBit(entries, bit)
+	onRight := bitAt(idx, bit) == 1
+
+	mine, other := entries[:split], entries[split:]
+	if onRight {
+		mine, other = entries[split:], entries[:split]
+	}
+
+	step := ProofStep{Hash: t.subtreeHash(other, bit+1), Left: onRight}
+	return append([]ProofStep{step}, t.pathSteps(mine, bit+1, idx)...)
+}
+
+// splitAtBit returns the index in entries (sorted ascending by index)
+// where bit flips from 0 to 1, so entries[:split] and entries[split:]
+// are the left and right children at that depth.
+func splitAtBit(entries []smtEntry, bit int) int {
+	return sort.Search(len(entries), func(i int) bool {
+		return bitAt(entries[i].index, bit) == 1
+	})
+}
+
+func bitAt(index []byte, bit int) int {
+	return int(index[bit/8]>>(7-uint(bit%8))) & 1
+}
+
+// VerifyInclusion reports whether proof shows that value is authenticated
+// under key in a SHA-256 SparseMerkleTree with the given root.
+func VerifyInclusion(root, key, value []byte, proof []ProofStep) bool {
+	return VerifyInclusionWithHasher(DefaultHasher, root, key, value, proof)
+}
+
+// VerifyInclusionWithHasher is VerifyInclusion for a tree built with
+// hasher.
+func VerifyInclusionWithHasher(hasher *Hasher, root, key, value []byte, proof []ProofStep) bool {
+	if !pathMatchesKey(hasher, key, proof) {
+		return false
+	}
+	return bytes.Equal(replayPath(hasher, hasher.leafHash(value), proof), root)
+}
+
+// VerifyNonInclusion reports whether proof shows that key has no value
+// authenticated in a SHA-256 SparseMerkleTree with the given root.
+func VerifyNonInclusion(root, key []byte, proof []ProofStep) bool {
+	return VerifyNonInclusionWithHasher(DefaultHasher, root, key, proof)
+}
+
+// VerifyNonInclusionWithHasher is VerifyNonInclusion for a tree built
+// with hasher.
+func VerifyNonInclusionWithHasher(hasher *Hasher, root, key []byte, proof []ProofStep) bool {
+	if !pathMatchesKey(hasher, key, proof) {
+		return false
+	}
+	emptyLeaf := make([]byte, hasher.Size())
+	return bytes.Equal(replayPath(hasher, emptyLeaf, proof), root)
+}
+
+// pathMatchesKey checks that proof's Left/Right steps are the ones
+// key's own index would take, so a proof generated for one key can't be
+// replayed to claim (non-)inclusion for another.
+func pathMatchesKey(hasher *Hasher, key []byte, proof []ProofStep) bool {
+	depth := hasher.Size() * 8
+	if len(proof) != depth {
+		return false
+	}
+	idx := hasher.hashKey(key)
+	for j, step := range proof {
+		bit := depth - 1 - j
+		if step.Left != (bitAt(idx, bit) == 1) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,103 @@
+package merkle
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSparseMerkleTree_SetGet(t *testing.T) {
+	tree := NewSparseMerkleTree()
+	tree.Set([]byte("alice"), []byte("100"))
+	tree.Set([]byte("bob"), []byte("200"))
+
+	value, proof, found := tree.Get([]byte("alice"))
+	if !found {
+		t.Fatal("Get(alice) found = false, want true")
+	}
+	if string(value) != "100" {
+		t.Errorf("Get(alice) value = %q, want %q", value, "100")
+	}
+	if !VerifyInclusion(tree.Root(), []byte("alice"), []byte("100"), proof) {
+		t.Error("VerifyInclusion failed for alice")
+	}
+}
+
+func TestSparseMerkleTree_SetOverwrites(t *testing.T) {
+	tree := NewSparseMerkleTree()
+	tree.Set([]byte("alice"), []byte("100"))
+	tree.Set([]byte("alice"), []byte("150"))
+
+	value, proof, found := tree.Get([]byte("alice"))
+	if !found || string(value) != "150" {
+		t.Fatalf("Get(alice) = (%q, %v), want (150, true)", value, found)
+	}
+	if !VerifyInclusion(tree.Root(), []byte("alice"), []byte("150"), proof) {
+		t.Error("VerifyInclusion failed for overwritten value")
+	}
+}
+
+func TestSparseMerkleTree_NonInclusion(t *testing.T) {
+	tree := NewSparseMerkleTree()
+	tree.Set([]byte("alice"), []byte("100"))
+
+	value, proof, found := tree.Get([]byte("carol"))
+	if found {
+		t.Fatalf("Get(carol) found = true, want false")
+	}
+	if value != nil {
+		t.Errorf("Get(carol) value = %q, want nil", value)
+	}
+	if !VerifyNonInclusion(tree.Root(), []byte("carol"), proof) {
+		t.Error("VerifyNonInclusion failed for carol")
+	}
+}
+
+func TestSparseMerkleTree_EmptyTreeNonInclusion(t *testing.T) {
+	tree := NewSparseMerkleTree()
+	_, proof, found := tree.Get([]byte("alice"))
+	if found {
+		t.Fatal("Get on empty tree found = true, want false")
+	}
+	if !VerifyNonInclusion(tree.Root(), []byte("alice"), proof) {
+		t.Error("VerifyNonInclusion failed on empty tree")
+	}
+}
+
+func TestSparseMerkleTree_RootChangesOnSet(t *testing.T) {
+	tree := NewSparseMerkleTree()
+	empty := tree.Root()
+	tree.Set([]byte("alice"), []byte("100"))
+	if bytes.Equal(empty, tree.Root()) {
+		t.Error("Root() did not change after Set")
+	}
+}
+
+func TestVerifyInclusion_RejectsWrongKey(t *testing.T) {
+	tree := NewSparseMerkleTree()
+	tree.Set([]byte("alice"), []byte("100"))
+	_, proof, _ := tree.Get([]byte("alice"))
+
+	if VerifyInclusion(tree.Root(), []byte("bob"), []byte("100"), proof) {
+		t.Error("VerifyInclusion accepted a proof for the wrong key")
+	}
+}
+
+func TestVerifyInclusion_RejectsWrongValue(t *testing.T) {
+	tree := NewSparseMerkleTree()
+	tree.Set([]byte("alice"), []byte("100"))
+	_, proof, _ := tree.Get([]byte("alice"))
+
+	if VerifyInclusion(tree.Root(), []byte("alice"), []byte("999"), proof) {
+		t.Error("VerifyInclusion accepted a proof for the wrong value")
+	}
+}
+
+func TestVerifyNonInclusion_RejectsSetKey(t *testing.T) {
+	tree := NewSparseMerkleTree()
+	tree.Set([]byte("alice"), []byte("100"))
+	_, proof, _ := tree.Get([]byte("alice"))
+
+	if VerifyNonInclusion(tree.Root(), []byte("alice"), proof) {
+		t.Error("VerifyNonInclusion accepted a proof for a key that is set")
+	}
+}